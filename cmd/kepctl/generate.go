@@ -68,6 +68,7 @@ func buildGenerateReleaseCommand(k *kepctl.Client) *cobra.Command {
 		},
 	}
 	f := cmd.Flags()
+	f.StringVar(&opts.Push, "push", "", "Push the generated manifest as an OCI artifact, e.g. oci://registry/repo:v1.22")
 	addRepoPathFlag(f, &opts.CommonArgs)
 	return cmd
 }
@@ -0,0 +1,47 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"k8s.io/enhancements/pkg/kepctl"
+)
+
+func main() {
+	if err := buildRootCommand().Execute(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func buildRootCommand() *cobra.Command {
+	k := &kepctl.Client{}
+
+	rootCmd := &cobra.Command{
+		Use:   "kepctl",
+		Short: "kepctl is the tool for working with enhancements in this repo",
+	}
+
+	rootCmd.AddCommand(buildCreateCommand(k))
+	rootCmd.AddCommand(buildReleaseCommand(k))
+	rootCmd.AddCommand(buildReleaseGroupCommand(k))
+
+	return rootCmd
+}
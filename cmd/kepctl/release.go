@@ -0,0 +1,149 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"k8s.io/enhancements/pkg/kepctl"
+)
+
+// buildReleaseGroupCommand builds the `kepctl release` command family, which
+// manages receipts that have already been created for a release (promoting,
+// withdrawing, linting and auditing them) and pulls published release
+// artifacts.
+func buildReleaseGroupCommand(k *kepctl.Client) *cobra.Command {
+	baseCmd := &cobra.Command{
+		Use:   "release",
+		Short: "Manage release receipts",
+	}
+	baseCmd.AddCommand(buildReleasePromoteCommand(k))
+	baseCmd.AddCommand(buildReleaseWithdrawCommand(k))
+	baseCmd.AddCommand(buildReleasePeekCommand(k))
+	baseCmd.AddCommand(buildReleasePullCommand(k))
+	baseCmd.AddCommand(buildReleaseLintCommand(k))
+	return baseCmd
+}
+
+func buildReleaseLintCommand(k *kepctl.Client) *cobra.Command {
+	opts := kepctl.LintReleaseOpts{}
+	cmd := &cobra.Command{
+		Use:     "lint [release]",
+		Short:   "Validate every receipt in a release against the lifecycle schema",
+		Long:    "Run the lifecycle schema validator across every receipt in a release directory, reporting violations as GitHub PR annotations",
+		Example: `  kepctl release lint v1.22`,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			return opts.Validate(args)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return k.LintRelease(&opts)
+		},
+	}
+
+	addRepoPathFlag(cmd.Flags(), &opts.CommonArgs)
+	return cmd
+}
+
+func buildReleasePullCommand(k *kepctl.Client) *cobra.Command {
+	opts := kepctl.ReleasePullOpts{}
+	cmd := &cobra.Command{
+		Use:     "pull [source]",
+		Short:   "Pull a release manifest artifact published by generate manifest --push",
+		Long:    "Fetch an OCI release manifest artifact and recreate the release directory layout locally",
+		Example: `  kepctl release pull oci://registry.example.com/enhancements/releases:v1.22`,
+		Args:    cobra.ExactArgs(1),
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			return opts.Validate(args)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return k.PullRelease(&opts)
+		},
+	}
+
+	addRepoPathFlag(cmd.Flags(), &opts.CommonArgs)
+	return cmd
+}
+
+func buildReleasePeekCommand(k *kepctl.Client) *cobra.Command {
+	opts := kepctl.PeekReleaseOpts{}
+	cmd := &cobra.Command{
+		Use:     "peek [release]",
+		Short:   "Audit a release's receipts against what KEPs declare",
+		Long:    "Walk the keps/ tree for KEPs targeting a release and report receipts that are missing, drifted, or stale",
+		Example: `  kepctl release peek v1.22 --output yaml`,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			return opts.Validate(args)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return k.PeekRelease(&opts)
+		},
+	}
+
+	f := cmd.Flags()
+	f.BoolVar(&opts.Remote, "remote", false, "Also check upstream KEPs via the GitHub API")
+	f.StringVar(&opts.Output, "output", "table", "Output format: table, yaml, or json")
+	addRepoPathFlag(f, &opts.CommonArgs)
+	return cmd
+}
+
+func buildReleasePromoteCommand(k *kepctl.Client) *cobra.Command {
+	opts := kepctl.ReceiptMoveOpts{}
+	cmd := &cobra.Command{
+		Use:     "promote [KEP]",
+		Short:   "Promote a KEP's receipt to a new stage",
+		Long:    "Move a KEP's receipt between the accepted, at-risk, removed and exception directories of a release",
+		Example: `  kepctl release promote sig-architecture/000-mykep --release v1.22 --to accepted`,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			return opts.Validate(args)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if opts.To == "" {
+				return fmt.Errorf("--to is required - one of accepted, at-risk, removed, exception")
+			}
+			return k.PromoteReceipt(&opts)
+		},
+	}
+
+	f := cmd.Flags()
+	f.StringVar(&opts.Release, "release", "", "Release To Target")
+	f.StringVar(&opts.To, "to", "", "Stage to promote the receipt to")
+	f.StringVar(&opts.By, "requested-by", "", "GitHub handle of the person requesting the move")
+	addRepoPathFlag(f, &opts.CommonArgs)
+	return cmd
+}
+
+func buildReleaseWithdrawCommand(k *kepctl.Client) *cobra.Command {
+	opts := kepctl.ReceiptMoveOpts{}
+	cmd := &cobra.Command{
+		Use:     "withdraw [KEP]",
+		Short:   "Withdraw a KEP's receipt from a release",
+		Long:    "Remove a KEP's receipt from a release, regardless of which stage it is currently in",
+		Example: `  kepctl release withdraw sig-architecture/000-mykep --release v1.22`,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			return opts.Validate(args)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return k.WithdrawReceipt(&opts)
+		},
+	}
+
+	f := cmd.Flags()
+	f.StringVar(&opts.Release, "release", "", "Release To Target")
+	addRepoPathFlag(f, &opts.CommonArgs)
+	return cmd
+}
@@ -0,0 +1,152 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package lifecycle is the schema and state-machine for release receipts:
+// what shape a receipt must have, and what moves it's legal to make between
+// the proposed, accepted, at-risk, removed and exception directories a
+// release is made up of.
+package lifecycle
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/xeipuuv/gojsonschema"
+	"gopkg.in/yaml.v3"
+	yamlutil "sigs.k8s.io/yaml"
+)
+
+// Stage names mirror the directories InitRelease creates under
+// releases/<release>.
+const (
+	StageProposed  = "proposed"
+	StageAccepted  = "accepted"
+	StageAtRisk    = "at-risk"
+	StageRemoved   = "removed"
+	StageException = "exception"
+)
+
+// Transitions encodes the legal moves a receipt can make between stages.
+// A move not listed here is illegal.
+var Transitions = map[string][]string{
+	StageProposed:  {StageAccepted, StageAtRisk, StageRemoved, StageException},
+	StageAccepted:  {StageAtRisk, StageRemoved},
+	StageAtRisk:    {StageAccepted, StageRemoved, StageException},
+	StageException: {StageAccepted},
+}
+
+// IsLegalTransition reports whether a receipt may move from one stage to
+// another.
+func IsLegalTransition(from, to string) bool {
+	for _, allowed := range Transitions[from] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateTransition returns an error describing why a move is illegal, or
+// nil if it is allowed.
+func ValidateTransition(from, to string) error {
+	if IsLegalTransition(from, to) {
+		return nil
+	}
+	return fmt.Errorf("illegal transition from %s to %s", from, to)
+}
+
+// receiptSchema is the JSON Schema a release receipt's YAML must satisfy.
+const receiptSchema = `{
+  "$schema": "http://json-schema.org/draft-07/schema#",
+  "type": "object",
+  "required": ["sig", "stage", "issue", "link"],
+  "properties": {
+    "kep": {"type": "string"},
+    "sig": {"type": "string", "pattern": "^sig-"},
+    "stage": {"type": "string", "enum": ["alpha", "beta", "stable", "deprecated"]},
+    "issue": {"type": "string", "pattern": "^https?://"},
+    "link": {"type": "string", "pattern": "^https?://"},
+    "prr-approver": {"type": "string"}
+  }
+}`
+
+var schemaLoader = gojsonschema.NewStringLoader(receiptSchema)
+
+// ValidationError is a single schema violation, annotated with the line in
+// the source YAML it came from where that can be determined.
+type ValidationError struct {
+	Field   string
+	Line    int
+	Message string
+}
+
+func (v ValidationError) String() string {
+	if v.Line > 0 {
+		return fmt.Sprintf("line %d: %s: %s", v.Line, v.Field, v.Message)
+	}
+	return fmt.Sprintf("%s: %s", v.Field, v.Message)
+}
+
+// Validate checks a receipt's raw YAML against the schema, returning one
+// ValidationError per violation with line numbers resolved from the source
+// document so failures can be reported inline (e.g. as GitHub PR
+// annotations) instead of just "malformed YAML".
+func Validate(b []byte) ([]ValidationError, error) {
+	lines, err := fieldLines(b)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse receipt YAML: %s", err)
+	}
+
+	j, err := yamlutil.YAMLToJSON(b)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse receipt YAML: %s", err)
+	}
+
+	result, err := gojsonschema.Validate(schemaLoader, gojsonschema.NewBytesLoader(j))
+	if err != nil {
+		return nil, fmt.Errorf("unable to validate receipt: %s", err)
+	}
+
+	violations := make([]ValidationError, 0, len(result.Errors()))
+	for _, e := range result.Errors() {
+		field := e.Field()
+		violations = append(violations, ValidationError{
+			Field:   field,
+			Line:    lines[strings.SplitN(field, ".", 2)[0]],
+			Message: e.Description(),
+		})
+	}
+	return violations, nil
+}
+
+// fieldLines maps each top-level field name in a YAML mapping document to
+// the line it's declared on.
+func fieldLines(b []byte) (map[string]int, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(b, &doc); err != nil {
+		return nil, err
+	}
+	lines := map[string]int{}
+	if len(doc.Content) == 0 {
+		return lines, nil
+	}
+	mapping := doc.Content[0]
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		key := mapping.Content[i]
+		lines[key.Value] = key.Line
+	}
+	return lines, nil
+}
@@ -0,0 +1,89 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kepctl
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"k8s.io/enhancements/pkg/kepctl/lifecycle"
+)
+
+type LintReleaseOpts struct {
+	CommonArgs
+	ReleaseVersion string
+}
+
+func (l *LintReleaseOpts) Validate(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("must provide a release version")
+	}
+	l.ReleaseVersion = args[0]
+	return nil
+}
+
+// LintRelease runs the lifecycle schema validator across every receipt in a
+// release directory and reports violations in a form suitable for GitHub PR
+// annotations, so CI can gate PRs touching releases/** on this check.
+func (c *Client) LintRelease(opts *LintReleaseOpts) error {
+	repoPath, err := c.findEnhancementsRepo(opts.CommonArgs)
+	if err != nil {
+		return fmt.Errorf("unable to lint release: unable to find enhancements repo: %s", err)
+	}
+
+	releasePath := filepath.Join(repoPath, "releases", opts.ReleaseVersion)
+	violationCount := 0
+	for _, stage := range []string{stageProposed, stageAccepted, stageAtRisk, stageRemoved, stageException} {
+		stagePath := filepath.Join(releasePath, stage)
+		files, err := ioutil.ReadDir(stagePath)
+		if err != nil {
+			return fmt.Errorf("unable to read %s receipts: %s", stage, err)
+		}
+		for _, file := range files {
+			if !strings.HasSuffix(file.Name(), ".yaml") {
+				continue
+			}
+			receiptPath := filepath.Join(stagePath, file.Name())
+			b, err := ioutil.ReadFile(receiptPath)
+			if err != nil {
+				return fmt.Errorf("unable to read %s: %s", receiptPath, err)
+			}
+			violations, err := lifecycle.Validate(b)
+			if err != nil {
+				fmt.Fprintf(c.Out, "::error file=%s::%s\n", receiptPath, err)
+				violationCount++
+				continue
+			}
+			for _, v := range violations {
+				if v.Line > 0 {
+					fmt.Fprintf(c.Out, "::error file=%s,line=%d::%s\n", receiptPath, v.Line, v.Message)
+				} else {
+					fmt.Fprintf(c.Out, "::error file=%s::%s\n", receiptPath, v.Message)
+				}
+				violationCount++
+			}
+		}
+	}
+
+	if violationCount > 0 {
+		return fmt.Errorf("%d violation(s) found in the receipts of release %s", violationCount, opts.ReleaseVersion)
+	}
+	fmt.Fprintf(c.Out, "All receipts in release %s are valid\n", opts.ReleaseVersion)
+	return nil
+}
@@ -0,0 +1,196 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kepctl
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/content/memory"
+	"oras.land/oras-go/v2/registry/remote"
+)
+
+const (
+	manifestMediaType = "application/vnd.k8s.enhancements.release.manifest.v1+yaml"
+	receiptMediaType  = "application/vnd.k8s.enhancements.release.receipt.v1+yaml"
+
+	// titleAnnotation records the relative path of a receipt within the
+	// release directory (e.g. "accepted/1234.yaml") so pull can recreate
+	// the layout InitRelease would have produced.
+	titleAnnotation = ocispec.AnnotationTitle
+
+	// layersDigestAnnotation records a digest computed over every layer
+	// descriptor at push time, so pull can detect a manifest whose layer
+	// list was tampered with or truncated after publication.
+	layersDigestAnnotation = "k8s.enhancements.layers-digest"
+)
+
+// pushReleaseArtifact packages a release's manifest and every receipt under
+// releasePath into an OCI artifact and pushes it to ref, e.g.
+// oci://registry.example.com/enhancements/releases:v1.22.
+func pushReleaseArtifact(ctx context.Context, releasePath string, manifestBytes []byte, ref string) error {
+	store := memory.New()
+
+	layers := []ocispec.Descriptor{}
+	for _, stage := range []string{stageProposed, stageAccepted, stageAtRisk, stageRemoved, stageException} {
+		stagePath := filepath.Join(releasePath, stage)
+		files, err := ioutil.ReadDir(stagePath)
+		if err != nil {
+			return fmt.Errorf("unable to read %s receipts: %s", stage, err)
+		}
+		for _, file := range files {
+			if !strings.HasSuffix(file.Name(), ".yaml") {
+				continue
+			}
+			b, err := ioutil.ReadFile(filepath.Join(stagePath, file.Name()))
+			if err != nil {
+				return fmt.Errorf("unable to read receipt %s/%s: %s", stage, file.Name(), err)
+			}
+			desc := content.NewDescriptorFromBytes(receiptMediaType, b)
+			desc.Annotations = map[string]string{
+				titleAnnotation: filepath.ToSlash(filepath.Join(stage, file.Name())),
+			}
+			if err := store.Push(ctx, desc, bytes.NewReader(b)); err != nil {
+				return fmt.Errorf("unable to stage receipt %s/%s: %s", stage, file.Name(), err)
+			}
+			layers = append(layers, desc)
+		}
+	}
+
+	manifestDesc := content.NewDescriptorFromBytes(manifestMediaType, manifestBytes)
+	manifestDesc.Annotations = map[string]string{titleAnnotation: "manifest.yaml"}
+	if err := store.Push(ctx, manifestDesc, bytes.NewReader(manifestBytes)); err != nil {
+		return fmt.Errorf("unable to stage manifest: %s", err)
+	}
+	layers = append([]ocispec.Descriptor{manifestDesc}, layers...)
+
+	packed, err := oras.PackManifest(ctx, store, oras.PackManifestVersion1_1, manifestMediaType, oras.PackManifestOptions{
+		Layers: layers,
+		ManifestAnnotations: map[string]string{
+			layersDigestAnnotation: computeLayersDigest(layers),
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("unable to pack release artifact: %s", err)
+	}
+
+	repoRef := strings.TrimPrefix(ref, "oci://")
+	repo, err := remote.NewRepository(repoRef)
+	if err != nil {
+		return fmt.Errorf("unable to resolve registry repository %s: %s", repoRef, err)
+	}
+	if _, err := oras.Copy(ctx, store, packed.Digest.String(), repo, tagFromRef(repoRef), oras.DefaultCopyOptions); err != nil {
+		return fmt.Errorf("unable to push release artifact to %s: %s", repoRef, err)
+	}
+	return nil
+}
+
+// pullReleaseArtifact fetches a release artifact from ref and materializes
+// the directory layout InitRelease would have created under destDir.
+func pullReleaseArtifact(ctx context.Context, ref, destDir string) error {
+	repoRef := strings.TrimPrefix(ref, "oci://")
+	repo, err := remote.NewRepository(repoRef)
+	if err != nil {
+		return fmt.Errorf("unable to resolve registry repository %s: %s", repoRef, err)
+	}
+
+	store := memory.New()
+	rootDesc, err := oras.Copy(ctx, repo, tagFromRef(repoRef), store, tagFromRef(repoRef), oras.DefaultCopyOptions)
+	if err != nil {
+		return fmt.Errorf("unable to pull release artifact from %s: %s", repoRef, err)
+	}
+	if rootDesc.MediaType != manifestMediaType && rootDesc.MediaType != ocispec.MediaTypeImageManifest {
+		return fmt.Errorf("unexpected top-level media type %s", rootDesc.MediaType)
+	}
+
+	rootBytes, err := content.FetchAll(ctx, store, rootDesc)
+	if err != nil {
+		return fmt.Errorf("unable to fetch release artifact manifest: %s", err)
+	}
+	var ociManifest ocispec.Manifest
+	if err := json.Unmarshal(rootBytes, &ociManifest); err != nil {
+		return fmt.Errorf("unable to parse release artifact manifest: %s", err)
+	}
+
+	if err := verifyManifestDigest(ociManifest); err != nil {
+		return fmt.Errorf("unable to verify release artifact: %s", err)
+	}
+
+	for _, layer := range ociManifest.Layers {
+		if layer.MediaType != manifestMediaType && layer.MediaType != receiptMediaType {
+			return fmt.Errorf("unexpected layer media type %s", layer.MediaType)
+		}
+		rel := layer.Annotations[titleAnnotation]
+		if rel == "" {
+			return fmt.Errorf("layer %s is missing a %s annotation", layer.Digest, titleAnnotation)
+		}
+		b, err := content.FetchAll(ctx, store, layer)
+		if err != nil {
+			return fmt.Errorf("unable to fetch %s: %s", rel, err)
+		}
+		dest := filepath.Join(destDir, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(dest), os.ModePerm); err != nil {
+			return fmt.Errorf("unable to create %s: %s", filepath.Dir(dest), err)
+		}
+		if err := ioutil.WriteFile(dest, b, 0644); err != nil {
+			return fmt.Errorf("unable to write %s: %s", dest, err)
+		}
+	}
+	return nil
+}
+
+// computeLayersDigest hashes the digest list of a manifest's layers, in
+// order, so push and pull can agree on a single value representing exactly
+// which layers the manifest claims to contain.
+func computeLayersDigest(layers []ocispec.Descriptor) string {
+	h := sha256.New()
+	for _, layer := range layers {
+		h.Write([]byte(layer.Digest.String()))
+	}
+	return fmt.Sprintf("sha256:%x", h.Sum(nil))
+}
+
+// verifyManifestDigest recomputes the digest over the manifest's layers and
+// confirms it matches the layersDigestAnnotation set at push time, so a
+// manifest whose layer list was tampered with or truncated after
+// publication is rejected rather than silently materialized.
+func verifyManifestDigest(m ocispec.Manifest) error {
+	claimed := m.Annotations[layersDigestAnnotation]
+	if claimed == "" {
+		return fmt.Errorf("release artifact manifest is missing a %s annotation", layersDigestAnnotation)
+	}
+	recomputed := computeLayersDigest(m.Layers)
+	if claimed != recomputed {
+		return fmt.Errorf("layers digest mismatch: manifest claims %s, recomputed %s", claimed, recomputed)
+	}
+	return nil
+}
+
+func tagFromRef(ref string) string {
+	parts := strings.Split(ref, ":")
+	return parts[len(parts)-1]
+}
@@ -0,0 +1,306 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kepctl
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"sigs.k8s.io/yaml"
+)
+
+const rawKEPBaseURL = "https://raw.githubusercontent.com/kubernetes/enhancements/master/keps"
+
+type PeekReleaseOpts struct {
+	CommonArgs
+	Release string
+	Remote  bool
+	Output  string
+}
+
+func (p *PeekReleaseOpts) Validate(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("must provide a release version")
+	}
+	p.Release = args[0]
+	switch p.Output {
+	case "", "table", "yaml", "json":
+	default:
+		return fmt.Errorf("invalid output format %q: must be table, yaml, or json", p.Output)
+	}
+	return nil
+}
+
+// milestoneSet mirrors the milestone.{alpha,beta,stable} block of a KEP's
+// kep.yaml.
+type milestoneSet struct {
+	Alpha  string `json:"alpha,omitempty"`
+	Beta   string `json:"beta,omitempty"`
+	Stable string `json:"stable,omitempty"`
+}
+
+// kepMilestoneMeta is the subset of a KEP's kep.yaml that peek cares about.
+type kepMilestoneMeta struct {
+	Stage           string       `json:"stage,omitempty"`
+	LatestMilestone string       `json:"latest-milestone,omitempty"`
+	Milestone       milestoneSet `json:"milestone,omitempty"`
+}
+
+// declaredStageFor returns the stage the KEP itself declares for the given
+// release, and whether the KEP targets that release at all.
+func (m kepMilestoneMeta) declaredStageFor(release string) (string, bool) {
+	switch release {
+	case m.Milestone.Alpha:
+		return "alpha", true
+	case m.Milestone.Beta:
+		return "beta", true
+	case m.Milestone.Stable:
+		return "stable", true
+	}
+	if m.LatestMilestone == release {
+		return m.Stage, true
+	}
+	return "", false
+}
+
+type kepRecord struct {
+	KEP    string // e.g. sig-architecture/000-mykep
+	Number string
+	SIG    string
+	Meta   kepMilestoneMeta
+}
+
+// PeekFinding describes a single disagreement (or lack thereof) between a
+// KEP's declared milestone and the release's receipts.
+type PeekFinding struct {
+	KEP      string `json:"kep"`
+	Number   string `json:"number"`
+	SIG      string `json:"sig,omitempty"`
+	Status   string `json:"status"` // missing, drifted, stale
+	Declared string `json:"declaredStage,omitempty"`
+	Actual   string `json:"actualStage,omitempty"`
+}
+
+// PeekResult is the structured diff document emitted by --output yaml/json.
+type PeekResult struct {
+	Release  string        `json:"release"`
+	Findings []PeekFinding `json:"findings"`
+}
+
+func walkLocalKEPs(repoPath string) ([]kepRecord, error) {
+	kepsRoot := filepath.Join(repoPath, "keps")
+	records := []kepRecord{}
+	err := filepath.Walk(kepsRoot, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || info.Name() != "kep.yaml" {
+			return nil
+		}
+		b, err := ioutil.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("unable to read %s: %s", path, err)
+		}
+		meta := kepMilestoneMeta{}
+		if err := yaml.Unmarshal(b, &meta); err != nil {
+			return fmt.Errorf("unable to parse %s: %s", path, err)
+		}
+		kepDir := filepath.Dir(path)
+		kep := strings.TrimPrefix(strings.TrimPrefix(kepDir, kepsRoot), string(filepath.Separator))
+		parts := strings.SplitN(kep, string(filepath.Separator), 2)
+		sig := parts[0]
+		number := strings.SplitN(filepath.Base(kepDir), "-", 2)[0]
+		records = append(records, kepRecord{
+			KEP:    filepath.ToSlash(kep),
+			Number: number,
+			SIG:    sig,
+			Meta:   meta,
+		})
+		return nil
+	})
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	return records, nil
+}
+
+// fetchRemoteKEPs augments the local keps/ tree with any upstream KEPs that
+// aren't checked out locally, by fetching their kep.yaml over HTTP. It is
+// best-effort: a KEP whose metadata can't be fetched is skipped rather than
+// failing the whole peek.
+func fetchRemoteKEPs(local []kepRecord) []kepRecord {
+	seen := map[string]bool{}
+	for _, r := range local {
+		seen[r.KEP] = true
+	}
+	remote := []kepRecord{}
+	resp, err := http.Get("https://api.github.com/repos/kubernetes/enhancements/git/trees/master?recursive=1")
+	if err != nil {
+		return remote
+	}
+	defer resp.Body.Close()
+	var tree struct {
+		Tree []struct {
+			Path string `json:"path"`
+		} `json:"tree"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&tree); err != nil {
+		return remote
+	}
+	for _, entry := range tree.Tree {
+		if !strings.HasPrefix(entry.Path, "keps/") || !strings.HasSuffix(entry.Path, "/kep.yaml") {
+			continue
+		}
+		kep := strings.TrimSuffix(strings.TrimPrefix(entry.Path, "keps/"), "/kep.yaml")
+		if seen[kep] {
+			continue
+		}
+		resp, err := http.Get(fmt.Sprintf("%s/%s/kep.yaml", rawKEPBaseURL, kep))
+		if err != nil {
+			continue
+		}
+		b, err := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			continue
+		}
+		meta := kepMilestoneMeta{}
+		if err := yaml.Unmarshal(b, &meta); err != nil {
+			continue
+		}
+		parts := strings.SplitN(kep, "/", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		number := strings.SplitN(parts[1], "-", 2)[0]
+		remote = append(remote, kepRecord{KEP: kep, Number: number, SIG: parts[0], Meta: meta})
+	}
+	return remote
+}
+
+// PeekRelease audits a release's receipts against what the KEPs in the tree
+// actually declare, surfacing KEPs that target the release but have no
+// receipt, receipts whose stage disagrees with the KEP, and receipts that
+// point at KEPs which no longer exist.
+func (c *Client) PeekRelease(opts *PeekReleaseOpts) error {
+	repoPath, err := c.findEnhancementsRepo(opts.CommonArgs)
+	if err != nil {
+		return fmt.Errorf("unable to peek release: unable to find enhancements repo: %s", err)
+	}
+
+	releasePath := filepath.Join(repoPath, "releases", opts.Release)
+	if _, err := os.Stat(releasePath); os.IsNotExist(err) {
+		return fmt.Errorf("unable to peek release: release directory does not exist: %s", err)
+	}
+
+	records, err := walkLocalKEPs(repoPath)
+	if err != nil {
+		return fmt.Errorf("unable to peek release: %s", err)
+	}
+	if opts.Remote {
+		records = append(records, fetchRemoteKEPs(records)...)
+	}
+
+	knownNumbers := map[string]bool{}
+	for _, r := range records {
+		knownNumbers[r.Number] = true
+	}
+
+	receiptStage := map[string]string{}
+	for _, dir := range []string{stageProposed, stageAccepted, stageAtRisk, stageRemoved, stageException} {
+		stagePath := filepath.Join(releasePath, dir)
+		files, err := ioutil.ReadDir(stagePath)
+		if err != nil {
+			return fmt.Errorf("unable to read %s metadata: %s", dir, err)
+		}
+		for _, file := range files {
+			if !strings.HasSuffix(file.Name(), ".yaml") {
+				continue
+			}
+			num := strings.TrimSuffix(file.Name(), ".yaml")
+			b, err := ioutil.ReadFile(filepath.Join(stagePath, file.Name()))
+			if err != nil {
+				return fmt.Errorf("unable to read receipt %s: %s", file.Name(), err)
+			}
+			content := releaseContent{}
+			if err := yaml.Unmarshal(b, &content); err != nil {
+				return fmt.Errorf("unable to parse receipt %s: %s", file.Name(), err)
+			}
+			receiptStage[num] = content.Stage
+		}
+	}
+
+	findings := []PeekFinding{}
+	for _, r := range records {
+		declared, targeted := r.Meta.declaredStageFor(opts.Release)
+		if !targeted {
+			continue
+		}
+		stage, ok := receiptStage[r.Number]
+		if !ok {
+			findings = append(findings, PeekFinding{
+				KEP: r.KEP, Number: r.Number, SIG: r.SIG, Status: "missing", Declared: declared,
+			})
+			continue
+		}
+		if stage != declared {
+			findings = append(findings, PeekFinding{
+				KEP: r.KEP, Number: r.Number, SIG: r.SIG, Status: "drifted", Declared: declared, Actual: stage,
+			})
+		}
+	}
+	for num, stage := range receiptStage {
+		if !knownNumbers[num] {
+			findings = append(findings, PeekFinding{Number: num, Status: "stale", Actual: stage})
+		}
+	}
+
+	result := PeekResult{Release: opts.Release, Findings: findings}
+	switch opts.Output {
+	case "yaml":
+		b, err := yaml.Marshal(result)
+		if err != nil {
+			return fmt.Errorf("unable to render peek result: %s", err)
+		}
+		fmt.Fprint(c.Out, string(b))
+	case "json":
+		b, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			return fmt.Errorf("unable to render peek result: %s", err)
+		}
+		fmt.Fprintln(c.Out, string(b))
+	default:
+		printPeekTable(c.Out, result)
+	}
+	return nil
+}
+
+func printPeekTable(out io.Writer, result PeekResult) {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "KEP\tNUMBER\tSTATUS\tDECLARED\tACTUAL")
+	for _, f := range result.Findings {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", f.KEP, f.Number, f.Status, f.Declared, f.Actual)
+	}
+	w.Flush()
+}
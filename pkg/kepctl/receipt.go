@@ -0,0 +1,247 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kepctl
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"k8s.io/enhancements/pkg/kepctl/lifecycle"
+	"sigs.k8s.io/yaml"
+)
+
+// stages are the directories InitRelease creates under releases/<release>,
+// in the order a receipt generally moves through them. These alias the
+// lifecycle package's stage names so the rest of this file doesn't need to
+// qualify every reference.
+const (
+	stageProposed  = lifecycle.StageProposed
+	stageAccepted  = lifecycle.StageAccepted
+	stageAtRisk    = lifecycle.StageAtRisk
+	stageRemoved   = lifecycle.StageRemoved
+	stageException = lifecycle.StageException
+)
+
+// kepFrontMatter is the subset of a KEP's kep.yaml this package cares about
+// when populating a receipt.
+type kepFrontMatter struct {
+	Stage        string   `json:"stage,omitempty"`
+	PRRApprovers []string `json:"prr-approvers,omitempty"`
+}
+
+func (k *kepFrontMatter) firstPRRApprover() string {
+	if len(k.PRRApprovers) == 0 {
+		return ""
+	}
+	return k.PRRApprovers[0]
+}
+
+func loadKEPMetadata(repoPath, kep string) (*kepFrontMatter, error) {
+	path := filepath.Join(repoPath, "keps", kep, "kep.yaml")
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read KEP metadata: %s", err)
+	}
+	meta := &kepFrontMatter{}
+	if err := yaml.Unmarshal(b, meta); err != nil {
+		return nil, fmt.Errorf("unable to parse KEP metadata: %s", err)
+	}
+	return meta, nil
+}
+
+type CreateReceiptOpts struct {
+	CommonArgs
+	Release string
+	Stage   string
+}
+
+func (c *CreateReceiptOpts) Validate(args []string) error {
+	if err := c.validateAndPopulateKEP(args); err != nil {
+		return err
+	}
+	if c.Release == "" {
+		return fmt.Errorf("target release is required to create a receipt")
+	}
+	if c.Stage == "" {
+		return fmt.Errorf("stage required to target the release")
+	}
+	return nil
+}
+
+// CreateReceipt targets a KEP for a release by writing a receipt into the
+// release's proposed/ directory, mirroring proposeKEP but sourcing as much
+// of the receipt content as possible from the KEP's own metadata.
+func (c *Client) CreateReceipt(opts *CreateReceiptOpts) error {
+	repoPath, err := c.findEnhancementsRepo(opts.CommonArgs)
+	if err != nil {
+		return fmt.Errorf("unable to create receipt: unable to find enhancements repo: %s", err)
+	}
+
+	releasePath := filepath.Join(repoPath, "releases", opts.Release)
+	if _, err := os.Stat(releasePath); os.IsNotExist(err) {
+		return fmt.Errorf(
+			"unable to create receipt: release %s has not been initialized, run kepctl generate release %s first",
+			opts.Release, opts.Release)
+	}
+
+	meta, err := loadKEPMetadata(repoPath, opts.KEP)
+	if err != nil {
+		return fmt.Errorf("unable to create receipt: %s", err)
+	}
+
+	receipt := releaseContent{
+		Number:      opts.Number,
+		Link:        generateKEPLink(opts.CommonArgs),
+		SIG:         opts.SIG,
+		Stage:       opts.Stage,
+		Issue:       generateIssueLink(opts.CommonArgs),
+		PRRApprover: meta.firstPRRApprover(),
+	}
+
+	fileName := fmt.Sprintf("%s.yaml", opts.Number)
+	proposedPath := filepath.Join(releasePath, stageProposed, fileName)
+	b, err := yaml.Marshal(receipt)
+	if err != nil {
+		return fmt.Errorf("unable to generate receipt: %s", err)
+	}
+	if violations, err := lifecycle.Validate(b); err != nil {
+		return fmt.Errorf("unable to validate receipt: %s", err)
+	} else if len(violations) > 0 {
+		return fmt.Errorf("unable to create receipt: %s", violations[0])
+	}
+	if err := ioutil.WriteFile(proposedPath, b, 0644); err != nil {
+		return fmt.Errorf("unable to write receipt: %s", err)
+	}
+	fmt.Fprintf(c.Out, "Created receipt for %s targeting release %s at %s\n", opts.Name, opts.Release, proposedPath)
+	return nil
+}
+
+type ReceiptMoveOpts struct {
+	CommonArgs
+	Release string
+	To      string
+	By      string
+}
+
+func (o *ReceiptMoveOpts) Validate(args []string) error {
+	if err := o.validateAndPopulateKEP(args); err != nil {
+		return err
+	}
+	if o.Release == "" {
+		return fmt.Errorf("target release is required")
+	}
+	return nil
+}
+
+// findReceipt locates which stage directory currently holds the receipt for
+// a KEP in a release, since the caller only knows the KEP, not its stage.
+func findReceipt(releasePath, kepNumber string) (string, error) {
+	fileName := fmt.Sprintf("%s.yaml", kepNumber)
+	for _, stage := range []string{stageProposed, stageAccepted, stageAtRisk, stageRemoved, stageException} {
+		path := filepath.Join(releasePath, stage, fileName)
+		if _, err := os.Stat(path); err == nil {
+			return stage, nil
+		}
+	}
+	return "", fmt.Errorf("no receipt found for KEP %s in this release", kepNumber)
+}
+
+// ensureApprover adds requestedBy to the destination stage's OWNERS file if
+// they aren't already listed as an approver, then re-sorts and rewrites it.
+func ensureApprover(stagePath, requestedBy string) error {
+	if requestedBy == "" {
+		return nil
+	}
+	ownersPath := filepath.Join(stagePath, "OWNERS")
+	b, err := ioutil.ReadFile(ownersPath)
+	if err != nil {
+		return fmt.Errorf("unable to read OWNERS for %s: %s", stagePath, err)
+	}
+	owners := &ownersFile{}
+	if err := yaml.Unmarshal(b, owners); err != nil {
+		return fmt.Errorf("unable to parse OWNERS for %s: %s", stagePath, err)
+	}
+	for _, approver := range owners.Approvers {
+		// InitRelease writes approvers as "<handle> // <role>".
+		handle := strings.TrimSpace(strings.SplitN(approver, "//", 2)[0])
+		if handle == requestedBy {
+			return nil
+		}
+	}
+	owners.Approvers = append(owners.Approvers, requestedBy)
+	sort.Strings(owners.Approvers)
+	return owners.save(ownersPath)
+}
+
+// PromoteReceipt moves a KEP's receipt from its current stage to opts.To,
+// refusing the move if it isn't a legal transition.
+func (c *Client) PromoteReceipt(opts *ReceiptMoveOpts) error {
+	repoPath, err := c.findEnhancementsRepo(opts.CommonArgs)
+	if err != nil {
+		return fmt.Errorf("unable to promote receipt: unable to find enhancements repo: %s", err)
+	}
+	releasePath := filepath.Join(repoPath, "releases", opts.Release)
+
+	from, err := findReceipt(releasePath, opts.Number)
+	if err != nil {
+		return fmt.Errorf("unable to promote receipt: %s", err)
+	}
+	if err := lifecycle.ValidateTransition(from, opts.To); err != nil {
+		return fmt.Errorf("unable to promote receipt: %s", err)
+	}
+
+	fileName := fmt.Sprintf("%s.yaml", opts.Number)
+	srcPath := filepath.Join(releasePath, from, fileName)
+	dstDir := filepath.Join(releasePath, opts.To)
+	dstPath := filepath.Join(dstDir, fileName)
+
+	if err := os.Rename(srcPath, dstPath); err != nil {
+		return fmt.Errorf("unable to promote receipt: %s", err)
+	}
+	if err := ensureApprover(dstDir, opts.By); err != nil {
+		return fmt.Errorf("unable to promote receipt: %s", err)
+	}
+	fmt.Fprintf(c.Out, "Promoted %s from %s to %s for release %s\n", opts.Name, from, opts.To, opts.Release)
+	return nil
+}
+
+// WithdrawReceipt removes a KEP's receipt from a release entirely, wherever
+// it currently lives, indicating it is no longer targeting that release.
+func (c *Client) WithdrawReceipt(opts *ReceiptMoveOpts) error {
+	repoPath, err := c.findEnhancementsRepo(opts.CommonArgs)
+	if err != nil {
+		return fmt.Errorf("unable to withdraw receipt: unable to find enhancements repo: %s", err)
+	}
+	releasePath := filepath.Join(repoPath, "releases", opts.Release)
+
+	from, err := findReceipt(releasePath, opts.Number)
+	if err != nil {
+		return fmt.Errorf("unable to withdraw receipt: %s", err)
+	}
+
+	fileName := fmt.Sprintf("%s.yaml", opts.Number)
+	srcPath := filepath.Join(releasePath, from, fileName)
+	if err := os.Remove(srcPath); err != nil {
+		return fmt.Errorf("unable to withdraw receipt: %s", err)
+	}
+	fmt.Fprintf(c.Out, "Withdrew %s from release %s (was %s)\n", opts.Name, opts.Release, from)
+	return nil
+}
@@ -18,6 +18,7 @@ package kepctl
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"io/ioutil"
 	"os"
@@ -26,6 +27,7 @@ import (
 	"strings"
 
 	"github.com/Masterminds/semver"
+	"k8s.io/enhancements/pkg/kepctl/lifecycle"
 	"sigs.k8s.io/yaml"
 )
 
@@ -99,49 +101,28 @@ func (c *Client) InitRelease(opts *InitReleaseOpts) error {
 		Approvers: approvers,
 	}
 
-	//release directories
 	//see https://docs.google.com/document/d/1qnfXjQCBrikbbu9F38hdzWEo5ZDBd57Cqi0wG0V6aGc/edit#
-	//1.20
-	// |- accepted
-	acceptedPath := filepath.Join(releasePath, "accepted")
-	fmt.Fprintf(c.Out, "===> creating %s accepted directory: %s\n", opts.ReleaseSemver, acceptedPath)
-	err = os.Mkdir(acceptedPath, os.ModePerm)
-	if err != nil {
-		return fmt.Errorf("unable to create accepted directory: %s", err)
-	}
-	owners.save(filepath.Join(acceptedPath, "OWNERS"))
-	// |- exceptions
-	exceptionPath := filepath.Join(releasePath, "exception")
-	fmt.Fprintf(c.Out, "===> creating %s exception directory: %s\n", opts.ReleaseSemver, exceptionPath)
-	err = os.Mkdir(exceptionPath, os.ModePerm)
-	if err != nil {
-		return fmt.Errorf("unable to create exception directory: %s", err)
-	}
-	owners.save(filepath.Join(exceptionPath, "OWNERS"))
-	// |- proposed
-	proposedPath := filepath.Join(releasePath, "proposed")
-	fmt.Fprintf(c.Out, "===> creating %s proposed directory: %s\n", opts.ReleaseSemver, proposedPath)
-	err = os.Mkdir(proposedPath, os.ModePerm)
-	if err != nil {
-		return fmt.Errorf("unable to create proposed directory: %s", err)
-	}
-	owners.save(filepath.Join(proposedPath, "OWNERS"))
-	// |- at-risk
-	atRiskPath := filepath.Join(releasePath, "at-risk")
-	fmt.Fprintf(c.Out, "===>  creating %s at risk directory: %s\n", opts.ReleaseSemver, atRiskPath)
-	err = os.Mkdir(atRiskPath, os.ModePerm)
-	if err != nil {
-		return fmt.Errorf("unable to create at-risk directory: %s", err)
-	}
-	owners.save(filepath.Join(atRiskPath, "OWNERS"))
-	// |- removed
-	removedPath := filepath.Join(releasePath, "removed")
-	fmt.Fprintf(c.Out, "===>  creating %s removed directory: %s\n", opts.ReleaseSemver, removedPath)
-	err = os.Mkdir(removedPath, os.ModePerm)
-	if err != nil {
-		return fmt.Errorf("unable to create removed directory: %s", err)
+	return scaffoldReleaseDirs(c, releasePath, opts.ReleaseSemver, owners, os.Mkdir)
+}
+
+// releaseStages are the accepted/exception/proposed/at-risk/removed
+// subdirectories every release directory is expected to have.
+var releaseStages = []string{stageAccepted, stageException, stageProposed, stageAtRisk, stageRemoved}
+
+// scaffoldReleaseDirs creates every release stage directory under
+// releasePath and writes an OWNERS file into each. mkdir is os.Mkdir for
+// InitRelease, which refuses to clobber an existing release, or os.MkdirAll
+// for PullRelease, which must recreate the full layout idempotently around
+// whichever stages a pulled artifact actually had receipts for.
+func scaffoldReleaseDirs(c *Client, releasePath, release string, owners *ownersFile, mkdir func(string, os.FileMode) error) error {
+	for _, stage := range releaseStages {
+		stagePath := filepath.Join(releasePath, stage)
+		fmt.Fprintf(c.Out, "===> creating %s %s directory: %s\n", release, stage, stagePath)
+		if err := mkdir(stagePath, os.ModePerm); err != nil {
+			return fmt.Errorf("unable to create %s directory: %s", stage, err)
+		}
+		owners.save(filepath.Join(stagePath, "OWNERS"))
 	}
-	owners.save(filepath.Join(removedPath, "OWNERS"))
 	return nil
 }
 
@@ -183,11 +164,12 @@ func generateIssueLink(opts CommonArgs) string {
 }
 
 type releaseContent struct {
-	Number string `json:"kep,omitempty"`
-	Link   string `json:"link"`
-	SIG    string `json:"sig"`
-	Stage  string `json:"stage"`
-	Issue  string `json:"issue"`
+	Number      string `json:"kep,omitempty"`
+	Link        string `json:"link"`
+	SIG         string `json:"sig"`
+	Stage       string `json:"stage"`
+	Issue       string `json:"issue"`
+	PRRApprover string `json:"prr-approver,omitempty"`
 }
 
 func (c *Client) proposeKEP(opts *ReleaseOpts) error {
@@ -215,21 +197,28 @@ func (c *Client) proposeKEP(opts *ReleaseOpts) error {
 	if err != nil {
 		return fmt.Errorf("unable to generate release proposal: %s", err)
 	}
+	if violations, err := lifecycle.Validate(b); err != nil {
+		return fmt.Errorf("unable to validate release proposal: %s", err)
+	} else if len(violations) > 0 {
+		return fmt.Errorf("unable to propose KEP for release: %s", violations[0])
+	}
 	ioutil.WriteFile(proposedPath, b, os.ModePerm)
 	fmt.Fprintf(c.Out, "Generated release proposal for %s at %s\n", opts.Name, proposedPath)
 	return nil
 }
 
 type releaseManifest struct {
-	Proposed []releaseContent `json:"proposed"`
-	Accepted []releaseContent `json:"accepted"`
-	AtRisk   []releaseContent `json:"at-risk"`
-	Removed  []releaseContent `json:"removed"`
+	Proposed   []releaseContent `json:"proposed"`
+	Accepted   []releaseContent `json:"accepted"`
+	AtRisk     []releaseContent `json:"at-risk"`
+	Removed    []releaseContent `json:"removed"`
+	Exceptions []releaseContent `json:"exception"`
 }
 
 type ReleaseManifestOpts struct {
 	CommonArgs
 	ReleaseVersion string
+	Push           string // e.g. oci://registry.example.com/enhancements/releases:v1.22
 }
 
 func (r *ReleaseManifestOpts) Validate(args []string) error {
@@ -241,6 +230,9 @@ func (r *ReleaseManifestOpts) Validate(args []string) error {
 	if err != nil {
 		return fmt.Errorf("invalid release version: %s", err)
 	}
+	if r.Push != "" && !strings.HasPrefix(r.Push, "oci://") {
+		return fmt.Errorf("--push must be an oci:// reference, got %s", r.Push)
+	}
 	return nil
 }
 
@@ -264,7 +256,6 @@ func (c *Client) GenerateRelease(opts *ReleaseManifestOpts) error {
 	if err != nil {
 		return fmt.Errorf("unable to read accepted metadata: %s", err)
 	}
-	var content releaseContent
 	acceptedKeps := []releaseContent{}
 	for _, file := range files {
 		if !strings.HasSuffix(file.Name(), ".yaml") {
@@ -274,6 +265,12 @@ func (c *Client) GenerateRelease(opts *ReleaseManifestOpts) error {
 		if err != nil {
 			return fmt.Errorf("error reading accepted KEP: %s", err)
 		}
+		if violations, vErr := lifecycle.Validate(b); vErr != nil {
+			return fmt.Errorf("error loading accepted KEP: %s", vErr)
+		} else if len(violations) > 0 {
+			return fmt.Errorf("error loading accepted KEP %s: %s", file.Name(), violations[0])
+		}
+		content := releaseContent{}
 		err = yaml.Unmarshal(b, &content)
 		if err != nil {
 			return fmt.Errorf("error loading accepted KEP: %s", err)
@@ -298,6 +295,12 @@ func (c *Client) GenerateRelease(opts *ReleaseManifestOpts) error {
 		if err != nil {
 			return fmt.Errorf("error reading at-risk KEP: %s", err)
 		}
+		if violations, vErr := lifecycle.Validate(b); vErr != nil {
+			return fmt.Errorf("error loading at-risk KEP: %s", vErr)
+		} else if len(violations) > 0 {
+			return fmt.Errorf("error loading at-risk KEP %s: %s", file.Name(), violations[0])
+		}
+		content := releaseContent{}
 		err = yaml.Unmarshal(b, &content)
 		if err != nil {
 			return fmt.Errorf("error loading at-risk KEP: %s", err)
@@ -322,6 +325,12 @@ func (c *Client) GenerateRelease(opts *ReleaseManifestOpts) error {
 		if err != nil {
 			return fmt.Errorf("error reading removed KEP: %s", err)
 		}
+		if violations, vErr := lifecycle.Validate(b); vErr != nil {
+			return fmt.Errorf("error loading removed KEP: %s", vErr)
+		} else if len(violations) > 0 {
+			return fmt.Errorf("error loading removed KEP %s: %s", file.Name(), violations[0])
+		}
+		content := releaseContent{}
 		err = yaml.Unmarshal(b, &content)
 		if err != nil {
 			return fmt.Errorf("error loading removed KEP: %s", err)
@@ -332,6 +341,36 @@ func (c *Client) GenerateRelease(opts *ReleaseManifestOpts) error {
 	}
 	manifest.Removed = removed
 	//get exceptions
+	exceptionPath := filepath.Join(releasePath, "exception")
+	files, err = ioutil.ReadDir(exceptionPath)
+	if err != nil {
+		return fmt.Errorf("unable to read exception metadata: %s", err)
+	}
+	exceptions := []releaseContent{}
+	for _, file := range files {
+		if !strings.HasSuffix(file.Name(), ".yaml") {
+			continue
+		}
+		b, err := ioutil.ReadFile(filepath.Join(exceptionPath, file.Name()))
+		if err != nil {
+			return fmt.Errorf("error reading exception KEP: %s", err)
+		}
+		if violations, vErr := lifecycle.Validate(b); vErr != nil {
+			return fmt.Errorf("error loading exception KEP: %s", vErr)
+		} else if len(violations) > 0 {
+			return fmt.Errorf("error loading exception KEP %s: %s", file.Name(), violations[0])
+		}
+		content := releaseContent{}
+		err = yaml.Unmarshal(b, &content)
+		if err != nil {
+			return fmt.Errorf("error loading exception KEP: %s", err)
+		}
+		num := strings.Split(file.Name(), ".")[0]
+		content.Number = num
+		exceptions = append(exceptions, content)
+	}
+	manifest.Exceptions = exceptions
+
 	b, err := yaml.Marshal(manifest)
 	if err != nil {
 		return fmt.Errorf("error generating manifest: %s", err)
@@ -346,5 +385,56 @@ func (c *Client) GenerateRelease(opts *ReleaseManifestOpts) error {
 	if err != nil {
 		return fmt.Errorf("unable to generate release manifest: %s", err)
 	}
+
+	if opts.Push != "" {
+		fmt.Fprintf(c.Out, "===> pushing release manifest to %s\n", opts.Push)
+		if err := pushReleaseArtifact(context.Background(), releasePath, b, opts.Push); err != nil {
+			return fmt.Errorf("unable to push release manifest: %s", err)
+		}
+	}
+	return nil
+}
+
+type ReleasePullOpts struct {
+	CommonArgs
+	Source string // e.g. oci://registry.example.com/enhancements/releases:v1.22
+}
+
+func (r *ReleasePullOpts) Validate(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("must provide an oci:// source reference")
+	}
+	r.Source = args[0]
+	if !strings.HasPrefix(r.Source, "oci://") {
+		return fmt.Errorf("source must be an oci:// reference, got %s", r.Source)
+	}
+	return nil
+}
+
+// PullRelease fetches a release manifest artifact published by GenerateRelease
+// --push and materializes the same directory layout InitRelease would have
+// created, without requiring a clone of the enhancements repo.
+func (c *Client) PullRelease(opts *ReleasePullOpts) error {
+	repoPath, err := c.findEnhancementsRepo(opts.CommonArgs)
+	if err != nil {
+		return fmt.Errorf("unable to pull release: unable to find enhancements repo: %s", err)
+	}
+
+	release := tagFromRef(strings.TrimPrefix(opts.Source, "oci://"))
+	destDir := filepath.Join(repoPath, "releases", release)
+	if err := os.MkdirAll(destDir, os.ModePerm); err != nil {
+		return fmt.Errorf("unable to pull release: %s", err)
+	}
+	// A pulled artifact may not have had receipts in every stage (e.g. no
+	// removed KEPs yet), so recreate the full layout InitRelease would have
+	// created before materializing whichever layers the artifact has.
+	owners := &ownersFile{Reviewers: []string{"release-team"}}
+	if err := scaffoldReleaseDirs(c, destDir, release, owners, os.MkdirAll); err != nil {
+		return fmt.Errorf("unable to pull release: %s", err)
+	}
+	if err := pullReleaseArtifact(context.Background(), opts.Source, destDir); err != nil {
+		return fmt.Errorf("unable to pull release: %s", err)
+	}
+	fmt.Fprintf(c.Out, "Pulled release artifact from %s into %s\n", opts.Source, destDir)
 	return nil
 }